@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"immich-go/immich"
+	"log"
+	"path/filepath"
+	"sync"
+
+	"github.com/ttacon/chalk"
+)
+
+// albumFolderSentinel, when passed as -album, derives one album per
+// parent folder (the name of the directory directly containing the asset,
+// relative to the walk root) instead of a single album.
+const albumFolderSentinel = "@folder"
+
+// albumBatchSize is the number of asset IDs accumulated per album before
+// they're flushed to the server, to avoid one API call per asset.
+const albumBatchSize = 100
+
+// albumDispatcher accumulates uploaded asset IDs per album name and
+// flushes them to the server in batches, creating albums on demand.
+type albumDispatcher struct {
+	ctx    context.Context
+	client *immich.ImmichClient
+	logger *log.Logger
+
+	mu       sync.Mutex
+	ids      map[string]string      // album name -> album ID
+	pending  map[string][]string    // album name -> asset IDs not yet flushed
+	creating map[string]*sync.Mutex // album name -> lock held for the duration of its CreateAlbum call
+}
+
+func newAlbumDispatcher(ctx context.Context, client *immich.ImmichClient, logger *log.Logger) (*albumDispatcher, error) {
+	d := &albumDispatcher{
+		ctx:      ctx,
+		client:   client,
+		logger:   logger,
+		ids:      map[string]string{},
+		pending:  map[string][]string{},
+		creating: map[string]*sync.Mutex{},
+	}
+	albums, err := client.GetAlbums(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range albums {
+		d.ids[a.Name] = a.ID
+	}
+	return d, nil
+}
+
+// albumNameFor returns the album a successfully uploaded asset at relPath
+// belongs to, given the -album flag value.
+func albumNameFor(album, relPath string) string {
+	if album != albumFolderSentinel {
+		return album
+	}
+	dir := filepath.Dir(relPath)
+	if dir == "." {
+		return "No Folder"
+	}
+	return filepath.Base(dir)
+}
+
+// Add queues assetID under the given album name, flushing immediately once
+// a full batch has accumulated.
+func (d *albumDispatcher) Add(name string, assetID string) {
+	d.mu.Lock()
+	d.pending[name] = append(d.pending[name], assetID)
+	flush := len(d.pending[name]) >= albumBatchSize
+	d.mu.Unlock()
+
+	if flush {
+		d.flush(name)
+	}
+}
+
+// Flush sends every remaining batch to the server. Call once after all
+// uploads have completed.
+func (d *albumDispatcher) Flush() {
+	d.mu.Lock()
+	names := make([]string, 0, len(d.pending))
+	for name, ids := range d.pending {
+		if len(ids) > 0 {
+			names = append(names, name)
+		}
+	}
+	d.mu.Unlock()
+
+	for _, name := range names {
+		d.flush(name)
+	}
+}
+
+func (d *albumDispatcher) flush(name string) {
+	d.mu.Lock()
+	ids := d.pending[name]
+	d.pending[name] = nil
+	d.mu.Unlock()
+
+	if len(ids) == 0 {
+		return
+	}
+
+	albumID, err := d.albumID(name)
+	if err != nil {
+		d.logger.Println(chalk.Red, "can't resolve album", name, err, chalk.ResetColor)
+		return
+	}
+	if err = d.client.AddAssetsToAlbum(d.ctx, albumID, ids); err != nil {
+		d.logger.Println(chalk.Red, "can't add", len(ids), "assets to album", name, err, chalk.ResetColor)
+	}
+}
+
+// albumID returns the ID of the album named name, creating it on the server
+// if it doesn't exist yet. Creation is serialized per name, with the lock
+// held across the CreateAlbum call, so that two goroutines racing to create
+// the same new album can't both miss the cache and create it twice.
+func (d *albumDispatcher) albumID(name string) (string, error) {
+	d.mu.Lock()
+	if id, ok := d.ids[name]; ok {
+		d.mu.Unlock()
+		return id, nil
+	}
+	lock, ok := d.creating[name]
+	if !ok {
+		lock = &sync.Mutex{}
+		d.creating[name] = lock
+	}
+	d.mu.Unlock()
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	d.mu.Lock()
+	if id, ok := d.ids[name]; ok {
+		d.mu.Unlock()
+		return id, nil
+	}
+	d.mu.Unlock()
+
+	album, err := d.client.CreateAlbum(d.ctx, name)
+	if err != nil {
+		return "", err
+	}
+	d.mu.Lock()
+	d.ids[name] = album.ID
+	d.mu.Unlock()
+	return album.ID, nil
+}
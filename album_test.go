@@ -0,0 +1,24 @@
+package main
+
+import "testing"
+
+func TestAlbumNameFor(t *testing.T) {
+	tests := []struct {
+		name    string
+		album   string
+		relPath string
+		want    string
+	}{
+		{"fixed album name", "Vacation", "2023/beach/photo.jpg", "Vacation"},
+		{"folder sentinel uses parent dir", albumFolderSentinel, "2023/beach/photo.jpg", "beach"},
+		{"folder sentinel at root falls back", albumFolderSentinel, "photo.jpg", "No Folder"},
+		{"no album requested", "", "2023/beach/photo.jpg", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := albumNameFor(tt.album, tt.relPath); got != tt.want {
+				t.Errorf("albumNameFor(%q, %q) = %q, want %q", tt.album, tt.relPath, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+// Package hashcache computes and caches content hashes of local files so
+// that repeated runs of immich-go don't have to re-read every file from
+// disk just to find out it was already uploaded.
+package hashcache
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// DefaultFileName is the sidecar cache file created next to where
+// immich-go is run from, unless the caller overrides it.
+const DefaultFileName = ".immich-go-cache"
+
+// compactionThreshold is the number of appended lines since the last
+// compaction that triggers another one, so a long-running or repeatedly
+// crashing import doesn't grow the log unbounded between clean exits.
+const compactionThreshold = 1000
+
+// entry is one line of the append-only cache log.
+type entry struct {
+	Path  string `json:"path"`
+	Mtime int64  `json:"mtime"`
+	Size  int64  `json:"size"`
+	Hash  string `json:"hash"`
+}
+
+func key(path string, mtime int64, size int64) string {
+	return fmt.Sprintf("%s\x00%d\x00%d", path, mtime, size)
+}
+
+// Cache is an append-only, line-delimited JSON log of file hashes keyed by
+// path+mtime+size. It is safe for concurrent use within a process and,
+// via an advisory lock file, across concurrent immich-go invocations.
+type Cache struct {
+	mu       sync.Mutex
+	path     string
+	byKey    map[string]entry
+	f        *os.File
+	lockPath string
+	appends  int // lines written since the last compaction
+}
+
+// Open loads (or creates) the cache file at path.
+func Open(path string) (*Cache, error) {
+	if path == "" {
+		path = DefaultFileName
+	}
+	c := &Cache{
+		path:     path,
+		byKey:    map[string]entry{},
+		lockPath: path + ".lock",
+	}
+	if err := c.lock(); err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		c.unlock()
+		return nil, fmt.Errorf("can't open hash cache %s: %w", path, err)
+	}
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var e entry
+		if err = json.Unmarshal(line, &e); err != nil {
+			continue // tolerate a truncated last line from a crashed run
+		}
+		c.byKey[key(e.Path, e.Mtime, e.Size)] = e
+	}
+	c.f = f
+	return c, nil
+}
+
+// Close compacts the cache to disk and releases the lock.
+func (c *Cache) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	err := c.compactLocked()
+	c.f.Close()
+	c.unlock()
+	return err
+}
+
+// Get returns the cached content hash for path, if the recorded mtime and
+// size still match.
+func (c *Cache) Get(path string, mtime int64, size int64) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.byKey[key(path, mtime, size)]
+	return e.Hash, ok
+}
+
+// Hash returns the cached hash of path if present, otherwise it streams
+// the file through SHA-1, records the result and returns it.
+func (c *Cache) Hash(path string, mtime int64, size int64, open func() (io.ReadCloser, error)) (string, error) {
+	if h, ok := c.Get(path, mtime, size); ok {
+		return h, nil
+	}
+
+	r, err := open()
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+
+	h := sha1.New()
+	if _, err = io.Copy(h, r); err != nil {
+		return "", fmt.Errorf("can't hash %s: %w", path, err)
+	}
+	sum := hex.EncodeToString(h.Sum(nil))
+
+	if err = c.put(entry{Path: path, Mtime: mtime, Size: size, Hash: sum}); err != nil {
+		return "", err
+	}
+	return sum, nil
+}
+
+func (c *Cache) put(e entry) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.byKey[key(e.Path, e.Mtime, e.Size)] = e
+
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+	if _, err = c.f.Write(b); err != nil {
+		return fmt.Errorf("can't append to hash cache: %w", err)
+	}
+	c.appends++
+	if c.appends >= compactionThreshold {
+		return c.compactLocked()
+	}
+	return nil
+}
+
+// compactLocked rewrites the cache file with one line per key, dropping the
+// duplicate lines accumulated by the append-only log, and reopens c.f so
+// the cache keeps appending to the compacted file. It runs periodically
+// from put, not just from Close, so a long-running import that's killed
+// repeatedly doesn't grow the log unbounded between clean exits.
+func (c *Cache) compactLocked() error {
+	tmp := c.path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return fmt.Errorf("can't compact hash cache: %w", err)
+	}
+	w := bufio.NewWriter(f)
+	for _, e := range c.byKey {
+		b, err := json.Marshal(e)
+		if err != nil {
+			f.Close()
+			return err
+		}
+		if _, err = w.Write(append(b, '\n')); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if err = w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	if err = os.Rename(tmp, c.path); err != nil {
+		return fmt.Errorf("can't compact hash cache: %w", err)
+	}
+
+	newF, err := os.OpenFile(c.path, os.O_RDWR|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("can't reopen compacted hash cache: %w", err)
+	}
+	c.f.Close()
+	c.f = newF
+	c.appends = 0
+	return nil
+}
@@ -0,0 +1,92 @@
+package hashcache
+
+import (
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func open(content string) func() (io.ReadCloser, error) {
+	return func() (io.ReadCloser, error) {
+		return io.NopCloser(strings.NewReader(content)), nil
+	}
+}
+
+func TestCacheHashCachesByPathMtimeSize(t *testing.T) {
+	dir := t.TempDir()
+	c, err := Open(filepath.Join(dir, "cache"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	calls := 0
+	opener := func() (io.ReadCloser, error) {
+		calls++
+		return io.NopCloser(strings.NewReader("hello")), nil
+	}
+
+	h1, err := c.Hash("a.jpg", 100, 5, opener)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h2, err := c.Hash("a.jpg", 100, 5, opener)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h1 != h2 {
+		t.Fatalf("hash changed between calls: %s != %s", h1, h2)
+	}
+	if calls != 1 {
+		t.Fatalf("opener called %d times, want 1 (second call should hit the cache)", calls)
+	}
+}
+
+func TestCacheSurvivesReopen(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "cache")
+
+	c1, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := c1.Hash("a.jpg", 100, 5, open("hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = c1.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	c2, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c2.Close()
+	got, ok := c2.Get("a.jpg", 100, 5)
+	if !ok {
+		t.Fatal("expected cached entry to survive reopen")
+	}
+	if got != want {
+		t.Fatalf("got %s, want %s", got, want)
+	}
+}
+
+func TestCacheCompactsPeriodically(t *testing.T) {
+	dir := t.TempDir()
+	c, err := Open(filepath.Join(dir, "cache"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	for i := 0; i < compactionThreshold+1; i++ {
+		if _, err = c.Hash("a.jpg", int64(i), 5, open("hello")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if c.appends >= compactionThreshold {
+		t.Fatalf("appends = %d, expected compaction to have reset it below %d", c.appends, compactionThreshold)
+	}
+}
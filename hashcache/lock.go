@@ -0,0 +1,46 @@
+package hashcache
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// staleLockAge is how long a lock file can exist before it's assumed to be
+// left over from a crashed invocation rather than held by a live one, and
+// safe to remove.
+const staleLockAge = 2 * time.Minute
+
+// lock takes a simple cross-process advisory lock by exclusively creating
+// a ".lock" file next to the cache, retrying for a short while if another
+// invocation currently holds it. A lock file older than staleLockAge is
+// assumed abandoned by a crashed process and removed so this call can
+// proceed instead of failing permanently.
+func (c *Cache) lock() error {
+	deadline := time.Now().Add(30 * time.Second)
+	staleChecked := false
+	for {
+		f, err := os.OpenFile(c.lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if err == nil {
+			return f.Close()
+		}
+		if !os.IsExist(err) {
+			return fmt.Errorf("can't lock hash cache: %w", err)
+		}
+		if !staleChecked {
+			staleChecked = true
+			if info, statErr := os.Stat(c.lockPath); statErr == nil && time.Since(info.ModTime()) > staleLockAge {
+				os.Remove(c.lockPath)
+				continue
+			}
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out waiting for hash cache lock %s", c.lockPath)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+func (c *Cache) unlock() {
+	os.Remove(c.lockPath)
+}
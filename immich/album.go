@@ -0,0 +1,90 @@
+package immich
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// Album is the subset of the Immich album object immich-go cares about.
+type Album struct {
+	ID   string `json:"id"`
+	Name string `json:"albumName"`
+}
+
+// GetAlbums lists every album visible to the authenticated user.
+func (ic *ImmichClient) GetAlbums(ctx context.Context) ([]Album, error) {
+	req, err := ic.newRequest(ctx, http.MethodGet, "/album", nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := ic.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("can't list albums: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("can't list albums, status %s", resp.Status)
+	}
+	albums := []Album{}
+	if err = decodeJSON(resp.Body, &albums); err != nil {
+		return nil, err
+	}
+	return albums, nil
+}
+
+// CreateAlbum creates a new, empty album with the given name.
+func (ic *ImmichClient) CreateAlbum(ctx context.Context, name string) (Album, error) {
+	b, err := json.Marshal(map[string]string{"albumName": name})
+	if err != nil {
+		return Album{}, err
+	}
+	req, err := ic.newRequest(ctx, http.MethodPost, "/album", bytes.NewReader(b))
+	if err != nil {
+		return Album{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := ic.do(req)
+	if err != nil {
+		return Album{}, fmt.Errorf("can't create album %q: %w", name, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		return Album{}, fmt.Errorf("can't create album %q, status %s", name, resp.Status)
+	}
+	var album Album
+	if err = decodeJSON(resp.Body, &album); err != nil {
+		return Album{}, err
+	}
+	return album, nil
+}
+
+// AddAssetsToAlbum adds assetIDs to the album identified by albumID, in a
+// single batched request.
+func (ic *ImmichClient) AddAssetsToAlbum(ctx context.Context, albumID string, assetIDs []string) error {
+	if len(assetIDs) == 0 {
+		return nil
+	}
+	b, err := json.Marshal(map[string][]string{"ids": assetIDs})
+	if err != nil {
+		return err
+	}
+	req, err := ic.newRequest(ctx, http.MethodPut, "/album/"+albumID+"/assets", bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := ic.do(req)
+	if err != nil {
+		return fmt.Errorf("can't add assets to album %s: %w", albumID, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("can't add assets to album %s, status %s", albumID, resp.Status)
+	}
+	return nil
+}
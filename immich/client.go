@@ -0,0 +1,168 @@
+package immich
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// ImmichClient holds the state needed to talk to an Immich server: the
+// server address, the API key used to authenticate and the UUID this
+// instance of immich-go presents itself as.
+type ImmichClient struct {
+	EndPoint   string // Server address (http://<your-ip>:2283/api or https://<your-domain>/api)
+	Key        string // API Key
+	DeviceUUID string // Device UUID sent with each asset
+
+	Resumable        bool   // Use TUS resumable uploads for large files
+	ResumableMinSize int64  // Minimum file size, in bytes, that triggers a resumable upload
+	TUSEndPoint      string // TUS endpoint, defaults to EndPoint+"/asset/upload" when empty
+	TUSStateFile     string // Path of the local file tracking in-progress TUS uploads
+
+	client *http.Client
+	tus    *tusState
+}
+
+// NewImmichClient creates an ImmichClient bound to the given server and API key.
+func NewImmichClient(endPoint string, key string, deviceUUID string) (*ImmichClient, error) {
+	if len(endPoint) == 0 {
+		return nil, fmt.Errorf("immich: must specify a server address")
+	}
+	if len(key) == 0 {
+		return nil, fmt.Errorf("immich: must specify an API key")
+	}
+	return &ImmichClient{
+		EndPoint:   endPoint,
+		Key:        key,
+		DeviceUUID: deviceUUID,
+		client: &http.Client{
+			Timeout: 10 * time.Minute,
+		},
+	}, nil
+}
+
+func (ic *ImmichClient) newRequest(ctx context.Context, method, url string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, ic.EndPoint+url, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("x-api-key", ic.Key)
+	return req, nil
+}
+
+// PingServer checks that the Immich server is reachable and responding.
+func (ic *ImmichClient) PingServer() error {
+	req, err := ic.newRequest(context.Background(), http.MethodGet, "/server-info/ping", nil)
+	if err != nil {
+		return err
+	}
+	resp, err := ic.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("can't ping the server: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("server ping failed with status %s", resp.Status)
+	}
+	return nil
+}
+
+// User is the subset of the Immich user object immich-go cares about.
+type User struct {
+	ID    string `json:"id"`
+	Email string `json:"email"`
+	Name  string `json:"name"`
+}
+
+// ValidateConnection checks the API key is valid and returns the authenticated user.
+func (ic *ImmichClient) ValidateConnection() (User, error) {
+	var user User
+	req, err := ic.newRequest(context.Background(), http.MethodGet, "/user/me", nil)
+	if err != nil {
+		return user, err
+	}
+	resp, err := ic.client.Do(req)
+	if err != nil {
+		return user, fmt.Errorf("can't validate connection: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return user, fmt.Errorf("can't validate connection, status %s", resp.Status)
+	}
+	err = json.NewDecoder(resp.Body).Decode(&user)
+	return user, err
+}
+
+// deviceAsset is the subset of the server's device-asset listing immich-go uses
+// to reconcile local files against what's already been uploaded.
+type deviceAsset struct {
+	ID            string `json:"id"`
+	Checksum      string `json:"checksum"`
+	DeviceAssetID string `json:"deviceAssetId"`
+}
+
+// GetUserAssetsByDeviceId returns the content checksums already known by the
+// server for the given device, so local files can be matched by content
+// rather than by name and size.
+func (ic *ImmichClient) GetUserAssetsByDeviceId(deviceID string) (*StringList, error) {
+	req, err := ic.newRequest(context.Background(), http.MethodGet, "/asset/device/"+deviceID, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := ic.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("can't get device assets: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("can't get device assets, status %s", resp.Status)
+	}
+	assets := []deviceAsset{}
+	if err = json.NewDecoder(resp.Body).Decode(&assets); err != nil {
+		return nil, err
+	}
+	list := NewStringList()
+	for _, a := range assets {
+		if a.Checksum != "" {
+			list.Push(a.Checksum)
+		}
+	}
+	return list, nil
+}
+
+// AssetIDForDeviceAssetID looks up the server-side asset ID for a
+// previously uploaded asset given the deviceAssetId it was uploaded under.
+// It exists because a completing TUS upload doesn't return the asset in its
+// response body the way a multipart upload does, so the caller has to ask
+// the server separately.
+func (ic *ImmichClient) AssetIDForDeviceAssetID(ctx context.Context, deviceAssetID string) (string, error) {
+	req, err := ic.newRequest(ctx, http.MethodGet, "/asset/device/"+ic.DeviceUUID, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := ic.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("can't get device assets: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("can't get device assets, status %s", resp.Status)
+	}
+	assets := []deviceAsset{}
+	if err = json.NewDecoder(resp.Body).Decode(&assets); err != nil {
+		return "", err
+	}
+	for _, a := range assets {
+		if a.DeviceAssetID == deviceAssetID {
+			return a.ID, nil
+		}
+	}
+	return "", fmt.Errorf("asset %s not found among device assets after upload", deviceAssetID)
+}
+
+func (ic *ImmichClient) do(req *http.Request) (*http.Response, error) {
+	return ic.client.Do(req)
+}
@@ -0,0 +1,27 @@
+package immich
+
+import "fmt"
+
+// LocalFileError wraps an error encountered while reading a local file,
+// as opposed to an error returned by the server.
+type LocalFileError error
+
+// UnsupportedMedia is returned when the server refuses an asset because its
+// media type isn't supported.
+type UnsupportedMedia struct {
+	Path string
+	Type string
+}
+
+func (e *UnsupportedMedia) Error() string {
+	return fmt.Sprintf("unsupported media type %q for %s", e.Type, e.Path)
+}
+
+// TooManyInternalError is returned as soon as the server answers an upload
+// with a 5xx status, signaling that the upload run should stop. A single
+// such answer is enough: consecutive errors are not counted.
+type TooManyInternalError struct{}
+
+func (e *TooManyInternalError) Error() string {
+	return "too many internal server errors, aborting"
+}
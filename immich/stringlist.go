@@ -0,0 +1,31 @@
+package immich
+
+import "sync"
+
+// StringList is a thread-safe set of strings, used to keep track of the
+// content hashes of assets already known to be present on the server.
+type StringList struct {
+	mu   sync.RWMutex
+	seen map[string]bool
+}
+
+// NewStringList creates an empty StringList.
+func NewStringList() *StringList {
+	return &StringList{
+		seen: map[string]bool{},
+	}
+}
+
+// Includes reports whether id is already in the list.
+func (sl *StringList) Includes(id string) bool {
+	sl.mu.RLock()
+	defer sl.mu.RUnlock()
+	return sl.seen[id]
+}
+
+// Push adds id to the list.
+func (sl *StringList) Push(id string) {
+	sl.mu.Lock()
+	defer sl.mu.Unlock()
+	sl.seen[id] = true
+}
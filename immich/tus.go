@@ -0,0 +1,278 @@
+package immich
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// errNotSeekable signals that a source can't be read a second time from an
+// arbitrary offset, so it can't be resumed through TUS; AssetUpload falls
+// back to a single-shot multipart upload when it sees this error.
+var errNotSeekable = errors.New("source is not seekable")
+
+// tusUploadState is the persisted state of one in-progress TUS upload.
+type tusUploadState struct {
+	ID        string `json:"id"`        // path+size identifier of the local asset
+	UploadURL string `json:"uploadUrl"` // URL returned by the server's TUS "Creation" response
+	Offset    int64  `json:"offset"`    // Last confirmed byte offset
+}
+
+// tusState tracks in-progress TUS uploads in a local state file so that a
+// later invocation of immich-go can resume them instead of restarting.
+type tusState struct {
+	mu   sync.Mutex
+	path string
+	byID map[string]tusUploadState
+}
+
+func (ic *ImmichClient) loadTUSState() (*tusState, error) {
+	if ic.tus != nil {
+		return ic.tus, nil
+	}
+	path := ic.TUSStateFile
+	if path == "" {
+		path = ".immich-go-tus-state.json"
+	}
+	st := &tusState{path: path, byID: map[string]tusUploadState{}}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return nil, fmt.Errorf("can't read TUS state file %s: %w", path, err)
+		}
+		ic.tus = st
+		return st, nil
+	}
+	if len(b) > 0 {
+		if err = json.Unmarshal(b, &st.byID); err != nil {
+			return nil, fmt.Errorf("can't parse TUS state file %s: %w", path, err)
+		}
+	}
+	ic.tus = st
+	return st, nil
+}
+
+func (st *tusState) save() error {
+	b, err := json.Marshal(st.byID)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(st.path, b, 0o600)
+}
+
+func (st *tusState) get(id string) (tusUploadState, bool) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	s, ok := st.byID[id]
+	return s, ok
+}
+
+func (st *tusState) set(s tusUploadState) error {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.byID[s.ID] = s
+	return st.save()
+}
+
+func (st *tusState) delete(id string) error {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	delete(st.byID, id)
+	return st.save()
+}
+
+// tusUpload uploads path via the TUS resumable protocol, creating the
+// upload on the server if needed and resuming from the last confirmed
+// offset found in the local state file. It returns errNotSeekable, without
+// having sent anything, when the source can't be resumed this way.
+func (ic *ImmichClient) tusUpload(ctx context.Context, fsys fs.FS, path string, info fs.FileInfo, wrap func(io.Reader) io.Reader, meta AssetMetadata) (AssetUploadResponse, error) {
+	id := fmt.Sprintf("%s-%d", path, info.Size())
+	deviceAssetID := fmt.Sprintf("%s-%d", filepath.Base(path), info.Size())
+
+	state, err := ic.loadTUSState()
+	if err != nil {
+		return AssetUploadResponse{}, err
+	}
+
+	f, err := fsys.Open(path)
+	if err != nil {
+		return AssetUploadResponse{}, LocalFileError(fmt.Errorf("can't open %s: %w", path, err))
+	}
+	defer f.Close()
+	seeker, ok := f.(io.ReadSeeker)
+	if !ok {
+		// A file inside a zip archive, for example, only offers a forward
+		// io.ReadCloser: resuming would need to seek, so it can't go
+		// through TUS at all.
+		return AssetUploadResponse{}, errNotSeekable
+	}
+
+	upload, ok := state.get(id)
+	if !ok {
+		upload, err = ic.tusCreate(ctx, id, deviceAssetID, info.Size(), meta)
+		if err != nil {
+			return AssetUploadResponse{}, err
+		}
+		if err = state.set(upload); err != nil {
+			return AssetUploadResponse{}, err
+		}
+	} else {
+		// The locally cached offset may be stale if the process died after
+		// a PATCH succeeded but before the new offset was persisted; ask
+		// the server what it actually has before resuming from it.
+		offset, err := ic.tusHead(ctx, upload.UploadURL)
+		if err != nil {
+			return AssetUploadResponse{}, err
+		}
+		upload.Offset = offset
+		if err = state.set(upload); err != nil {
+			return AssetUploadResponse{}, err
+		}
+	}
+
+	for upload.Offset < info.Size() {
+		if _, err = seeker.Seek(upload.Offset, io.SeekStart); err != nil {
+			return AssetUploadResponse{}, err
+		}
+		offset, err := ic.tusPatch(ctx, upload, wrapReader(wrap, seeker))
+		if err != nil {
+			return AssetUploadResponse{}, err
+		}
+		upload.Offset = offset
+		if err = state.set(upload); err != nil {
+			return AssetUploadResponse{}, err
+		}
+	}
+
+	if err = state.delete(id); err != nil {
+		return AssetUploadResponse{}, err
+	}
+
+	// The TUS completion response carries no asset, unlike the multipart
+	// path's JSON body: look the real asset ID up by the deviceAssetId we
+	// uploaded it under.
+	assetID, err := ic.AssetIDForDeviceAssetID(ctx, deviceAssetID)
+	if err != nil {
+		return AssetUploadResponse{}, fmt.Errorf("uploaded %s but can't resolve its asset ID: %w", path, err)
+	}
+	return AssetUploadResponse{ID: assetID}, nil
+}
+
+func (ic *ImmichClient) tusEndpoint() string {
+	if ic.TUSEndPoint != "" {
+		return ic.TUSEndPoint
+	}
+	return ic.EndPoint + "/asset/upload"
+}
+
+// tusMetadataHeader builds the value of the TUS Creation extension's
+// Upload-Metadata header: a comma-separated list of "key base64(value)"
+// pairs, as required by the spec (raw values aren't allowed).
+func tusMetadataHeader(deviceID, deviceAssetID string, meta AssetMetadata) string {
+	pairs := []string{
+		"deviceId " + base64.StdEncoding.EncodeToString([]byte(deviceID)),
+		"deviceAssetId " + base64.StdEncoding.EncodeToString([]byte(deviceAssetID)),
+	}
+	if !meta.CreatedAt.IsZero() {
+		pairs = append(pairs, "fileCreatedAt "+base64.StdEncoding.EncodeToString([]byte(meta.CreatedAt.Format(time.RFC3339))))
+	}
+	if !meta.ModifiedAt.IsZero() {
+		pairs = append(pairs, "fileModifiedAt "+base64.StdEncoding.EncodeToString([]byte(meta.ModifiedAt.Format(time.RFC3339))))
+	}
+	if meta.HasGeoData {
+		pairs = append(pairs, "latitude "+base64.StdEncoding.EncodeToString([]byte(strconv.FormatFloat(meta.Latitude, 'f', -1, 64))))
+		pairs = append(pairs, "longitude "+base64.StdEncoding.EncodeToString([]byte(strconv.FormatFloat(meta.Longitude, 'f', -1, 64))))
+	}
+	header := pairs[0]
+	for _, p := range pairs[1:] {
+		header += "," + p
+	}
+	return header
+}
+
+func (ic *ImmichClient) tusCreate(ctx context.Context, id, deviceAssetID string, size int64, meta AssetMetadata) (tusUploadState, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ic.tusEndpoint(), nil)
+	if err != nil {
+		return tusUploadState{}, err
+	}
+	req.Header.Set("x-api-key", ic.Key)
+	req.Header.Set("Tus-Resumable", "1.0.0")
+	req.Header.Set("Upload-Length", strconv.FormatInt(size, 10))
+	req.Header.Set("Upload-Metadata", tusMetadataHeader(ic.DeviceUUID, deviceAssetID, meta))
+
+	resp, err := ic.client.Do(req)
+	if err != nil {
+		return tusUploadState{}, fmt.Errorf("can't create TUS upload: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		return tusUploadState{}, fmt.Errorf("TUS create failed with status %s", resp.Status)
+	}
+	return tusUploadState{
+		ID:        id,
+		UploadURL: resp.Header.Get("Location"),
+		Offset:    0,
+	}, nil
+}
+
+// tusHead asks the server for the current offset of an in-progress upload,
+// so a resume can reconcile against it instead of trusting a local offset
+// that may not have been persisted after the last successful PATCH.
+func (ic *ImmichClient) tusHead(ctx context.Context, uploadURL string) (int64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, uploadURL, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("x-api-key", ic.Key)
+	req.Header.Set("Tus-Resumable", "1.0.0")
+
+	resp, err := ic.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("TUS HEAD failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("TUS HEAD failed with status %s", resp.Status)
+	}
+	offset, err := strconv.ParseInt(resp.Header.Get("Upload-Offset"), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("TUS server returned an invalid Upload-Offset: %w", err)
+	}
+	return offset, nil
+}
+
+func (ic *ImmichClient) tusPatch(ctx context.Context, upload tusUploadState, body io.Reader) (int64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPatch, upload.UploadURL, body)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("x-api-key", ic.Key)
+	req.Header.Set("Tus-Resumable", "1.0.0")
+	req.Header.Set("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+	req.Header.Set("Content-Type", "application/offset+octet-stream")
+
+	resp, err := ic.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("TUS PATCH failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		return 0, fmt.Errorf("TUS PATCH failed with status %s", resp.Status)
+	}
+	offset, err := strconv.ParseInt(resp.Header.Get("Upload-Offset"), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("TUS server returned an invalid Upload-Offset: %w", err)
+	}
+	return offset, nil
+}
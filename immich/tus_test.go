@@ -0,0 +1,110 @@
+package immich
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestTusMetadataHeaderEncodesValues(t *testing.T) {
+	meta := AssetMetadata{
+		CreatedAt: time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC),
+	}
+	header := tusMetadataHeader("device-1", "photo.jpg-100", meta)
+
+	pairs := strings.Split(header, ",")
+	got := map[string]string{}
+	for _, p := range pairs {
+		parts := strings.SplitN(p, " ", 2)
+		if len(parts) != 2 {
+			t.Fatalf("malformed pair %q in header %q", p, header)
+		}
+		got[parts[0]] = parts[1]
+	}
+
+	wantDeviceID := base64.StdEncoding.EncodeToString([]byte("device-1"))
+	if got["deviceId"] != wantDeviceID {
+		t.Errorf("deviceId = %q, want %q", got["deviceId"], wantDeviceID)
+	}
+	wantAssetID := base64.StdEncoding.EncodeToString([]byte("photo.jpg-100"))
+	if got["deviceAssetId"] != wantAssetID {
+		t.Errorf("deviceAssetId = %q, want %q", got["deviceAssetId"], wantAssetID)
+	}
+	wantCreatedAt := base64.StdEncoding.EncodeToString([]byte(meta.CreatedAt.Format(time.RFC3339)))
+	if got["fileCreatedAt"] != wantCreatedAt {
+		t.Errorf("fileCreatedAt = %q, want %q", got["fileCreatedAt"], wantCreatedAt)
+	}
+	if _, ok := got["fileModifiedAt"]; ok {
+		t.Error("fileModifiedAt should be omitted when ModifiedAt is zero")
+	}
+}
+
+// TestTusUploadReturnsRealAssetID drives tusUpload against a fake TUS
+// server and checks that the returned AssetUploadResponse.ID is the
+// server-side asset ID, not the TUS resource URL: the completion response
+// carries no body, so tusUpload has to look the asset up afterwards.
+func TestTusUploadReturnsRealAssetID(t *testing.T) {
+	const content = "fake video bytes"
+	const wantAssetID = "asset-123"
+
+	var deviceAssetID string
+	mux := http.NewServeMux()
+	var serverURL string
+	mux.HandleFunc("/asset/upload", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Fatalf("unexpected method %s on /asset/upload", r.Method)
+		}
+		w.Header().Set("Location", serverURL+"/tus/1")
+		w.WriteHeader(http.StatusCreated)
+	})
+	mux.HandleFunc("/tus/1", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPatch:
+			w.Header().Set("Upload-Offset", fmt.Sprintf("%d", len(content)))
+			w.WriteHeader(http.StatusNoContent)
+		case http.MethodHead:
+			w.Header().Set("Upload-Offset", "0")
+			w.WriteHeader(http.StatusOK)
+		default:
+			t.Fatalf("unexpected method %s on /tus/1", r.Method)
+		}
+	})
+	mux.HandleFunc("/asset/device/", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode([]deviceAsset{
+			{ID: wantAssetID, Checksum: "sum", DeviceAssetID: deviceAssetID},
+		})
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	serverURL = server.URL
+
+	ic, err := NewImmichClient(server.URL, "key", "device-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ic.TUSEndPoint = server.URL + "/asset/upload"
+	ic.TUSStateFile = t.TempDir() + "/tus-state.json"
+	ic.client = server.Client()
+
+	fsys := fstest.MapFS{"video.mp4": &fstest.MapFile{Data: []byte(content)}}
+	info, err := fsys.Stat("video.mp4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	deviceAssetID = fmt.Sprintf("video.mp4-%d", info.Size())
+
+	resp, err := ic.tusUpload(context.Background(), fsys, "video.mp4", info, nil, AssetMetadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.ID != wantAssetID {
+		t.Errorf("resp.ID = %q, want %q (the server-side asset ID, not a TUS URL)", resp.ID, wantAssetID)
+	}
+}
@@ -0,0 +1,125 @@
+package immich
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// AssetUploadResponse is the server's answer to an asset upload.
+type AssetUploadResponse struct {
+	ID        string `json:"id"`
+	Duplicate bool   `json:"duplicate"`
+}
+
+// AssetMetadata carries the asset timestamps and GPS coordinates known
+// ahead of upload, for example from a Google Photos Takeout sidecar. Zero
+// values are omitted from the request, letting the server fall back to its
+// own defaults.
+type AssetMetadata struct {
+	CreatedAt  time.Time
+	ModifiedAt time.Time
+	Latitude   float64
+	Longitude  float64
+	HasGeoData bool
+}
+
+// AssetUpload sends the file at path (opened from fsys) to the server.
+// When the client is configured as Resumable and the file is at least
+// ResumableMinSize bytes, the upload goes through the TUS protocol so it
+// can be resumed after an interruption; otherwise it falls back to a
+// single multipart/form-data POST. wrap, if non-nil, wraps the request
+// body reader so callers can observe upload progress; pass nil to upload
+// without instrumentation.
+func (ic *ImmichClient) AssetUpload(ctx context.Context, fsys fs.FS, path string, wrap func(io.Reader) io.Reader, meta AssetMetadata) (AssetUploadResponse, error) {
+	info, err := fs.Stat(fsys, path)
+	if err != nil {
+		return AssetUploadResponse{}, LocalFileError(fmt.Errorf("can't stat %s: %w", path, err))
+	}
+
+	if ic.Resumable && info.Size() >= ic.ResumableMinSize {
+		resp, err := ic.tusUpload(ctx, fsys, path, info, wrap, meta)
+		if !errors.Is(err, errNotSeekable) {
+			return resp, err
+		}
+		// The source can't be read twice from an arbitrary offset (e.g. a
+		// file inside a zip archive), so TUS resuming isn't possible: fall
+		// back to a single-shot upload instead of hard-failing.
+	}
+	return ic.multipartUpload(ctx, fsys, path, info, wrap, meta)
+}
+
+func wrapReader(wrap func(io.Reader) io.Reader, r io.Reader) io.Reader {
+	if wrap == nil {
+		return r
+	}
+	return wrap(r)
+}
+
+func (ic *ImmichClient) multipartUpload(ctx context.Context, fsys fs.FS, path string, info fs.FileInfo, wrap func(io.Reader) io.Reader, meta AssetMetadata) (AssetUploadResponse, error) {
+	f, err := fsys.Open(path)
+	if err != nil {
+		return AssetUploadResponse{}, LocalFileError(fmt.Errorf("can't open %s: %w", path, err))
+	}
+	defer f.Close()
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("assetData", filepath.Base(path))
+	if err != nil {
+		return AssetUploadResponse{}, err
+	}
+	if _, err = io.Copy(part, f); err != nil {
+		return AssetUploadResponse{}, err
+	}
+	_ = writer.WriteField("deviceId", ic.DeviceUUID)
+	_ = writer.WriteField("deviceAssetId", fmt.Sprintf("%s-%d", filepath.Base(path), info.Size()))
+	if !meta.CreatedAt.IsZero() {
+		_ = writer.WriteField("fileCreatedAt", meta.CreatedAt.Format(time.RFC3339))
+	}
+	if !meta.ModifiedAt.IsZero() {
+		_ = writer.WriteField("fileModifiedAt", meta.ModifiedAt.Format(time.RFC3339))
+	}
+	if meta.HasGeoData {
+		_ = writer.WriteField("latitude", strconv.FormatFloat(meta.Latitude, 'f', -1, 64))
+		_ = writer.WriteField("longitude", strconv.FormatFloat(meta.Longitude, 'f', -1, 64))
+	}
+	if err = writer.Close(); err != nil {
+		return AssetUploadResponse{}, err
+	}
+
+	req, err := ic.newRequest(ctx, http.MethodPost, "/asset/upload", wrapReader(wrap, body))
+	if err != nil {
+		return AssetUploadResponse{}, err
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	resp, err := ic.do(req)
+	if err != nil {
+		return AssetUploadResponse{}, fmt.Errorf("can't upload %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusUnsupportedMediaType:
+		return AssetUploadResponse{}, &UnsupportedMedia{Path: path, Type: filepath.Ext(path)}
+	case resp.StatusCode >= http.StatusInternalServerError:
+		return AssetUploadResponse{}, &TooManyInternalError{}
+	case resp.StatusCode >= http.StatusBadRequest:
+		return AssetUploadResponse{}, fmt.Errorf("upload of %s failed with status %s", path, resp.Status)
+	}
+
+	var uploadResp AssetUploadResponse
+	if err = decodeJSON(resp.Body, &uploadResp); err != nil {
+		return AssetUploadResponse{}, err
+	}
+	return uploadResp, nil
+}
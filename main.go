@@ -1,29 +1,33 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"flag"
 	"fmt"
+	"immich-go/hashcache"
 	"immich-go/immich"
+	"immich-go/progress"
+	"immich-go/source"
+	"io"
 	"io/fs"
 	"log"
 	"os"
+	"os/signal"
 	"path/filepath"
-	"regexp"
 	"runtime"
-	"strconv"
 	"strings"
 	"sync/atomic"
+	"time"
 
 	"github.com/ttacon/chalk"
 )
 
-var stripSpaces = regexp.MustCompile(`\s+`)
-
 func main() {
 
 	app := Application{
-		Logger: log.New(os.Stdout, "", log.LstdFlags),
+		Logger:    log.New(os.Stdout, "", log.LstdFlags),
+		ErrLogger: log.New(os.Stderr, "", log.LstdFlags),
 	}
 
 	deviceID, err := os.Hostname()
@@ -42,11 +46,17 @@ func main() {
 	flag.StringVar(&app.Album, "album", "", "Create albums for assets based on the parent folder or a given name")
 	// flag.BoolVar(&app.Import, "import", false, "Import instead of upload")
 	flag.StringVar(&app.DeviceUUID, "device-uuid", deviceID, "Set a device UUID")
+	flag.BoolVar(&app.Resumable, "resumable", false, "Use TUS resumable uploads for large files")
+	flag.Int64Var(&app.ResumableMinSize, "resumable-min-size", 100*1024*1024, "Minimum file size, in bytes, to upload via TUS instead of a single request")
+	flag.StringVar(&app.TUSEndPoint, "tus-endpoint", "", "TUS upload endpoint (defaults to <server>/asset/upload)")
+	flag.StringVar(&app.HashCacheFile, "hash-cache", hashcache.DefaultFileName, "Path to the local content-hash cache file")
+	flag.BoolVar(&app.NoProgress, "no-progress", false, "Disable the progress bar")
+	flag.BoolVar(&app.Silent, "silent", false, "Suppress all non-error output, including the progress bar; errors are still printed to stderr")
 	flag.Parse()
 	app.Paths = flag.Args()
 	err = app.Run()
 	if err != nil {
-		app.Logger.Print(chalk.Red, err.Error(), chalk.ResetColor)
+		app.ErrLogger.Print(chalk.Red, err.Error(), chalk.ResetColor)
 		os.Exit(1)
 	}
 }
@@ -61,13 +71,22 @@ type Application struct {
 	Album               string               // Create albums for assets based on the parent folder or a given name
 	Import              bool                 // Import instead of upload
 	DeviceUUID          string               // Set a device UUID
+	Resumable           bool                 // Use TUS resumable uploads for large files
+	ResumableMinSize    int64                // Minimum file size, in bytes, to upload via TUS
+	TUSEndPoint         string               // TUS upload endpoint, defaults to <server>/asset/upload
+	HashCacheFile       string               // Path to the local content-hash cache file
+	NoProgress          bool                 // Disable the progress bar
+	Silent              bool                 // Suppress all non-error output; errors still go to ErrLogger
 	Paths               []string             // Path to explore
 	OnLineAssets        *immich.StringList   // Keep track on published assets
-	Logger              *log.Logger          // Program's logger
+	Logger              *log.Logger          // Program's logger, silenced by -silent
+	ErrLogger           *log.Logger          // Error logger, always writes to stderr even under -silent
 	Immich              *immich.ImmichClient // Immich client
 	Worker              *Worker              // Worker to manage multithread
+	Progress            progress.Reporter    // Upload progress reporter
 	mediaCount          atomic.Int64         // Count uploaded medias
 	tooManyServerErrors chan any             // Signal of permanent server error condition
+	albums              *albumDispatcher     // Accumulates uploaded assets into albums, if -album is set
 }
 
 func (app *Application) CheckParameters() error {
@@ -88,9 +107,16 @@ func (app *Application) CheckParameters() error {
 }
 
 type localAsset struct {
-	ID   string
-	Fsys fs.FS
-	Path string
+	ID           string
+	Fsys         fs.FS
+	Path         string
+	Size         int64
+	CreatedAt    time.Time // From a Takeout sidecar, if any
+	ModifiedAt   time.Time // From a Takeout sidecar, if any
+	Latitude     float64   // From a Takeout sidecar's geoData, if any
+	Longitude    float64   // From a Takeout sidecar's geoData, if any
+	HasGeoData   bool
+	TakeoutAlbum string // Album title from a Takeout album's metadata.json, if any
 }
 
 func (app *Application) Run() error {
@@ -100,10 +126,25 @@ func (app *Application) Run() error {
 		return err
 	}
 
+	if app.Silent {
+		app.Logger.SetOutput(io.Discard)
+	}
+	if app.NoProgress || app.Silent {
+		app.Progress = progress.Nop{}
+	} else {
+		app.Progress = progress.NewPBReporter()
+	}
+
+	ctx, stopSignal := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stopSignal()
+
 	app.Immich, err = immich.NewImmichClient(app.EndPoint, app.Key, app.DeviceUUID)
 	if err != nil {
 		return err
 	}
+	app.Immich.Resumable = app.Resumable
+	app.Immich.ResumableMinSize = app.ResumableMinSize
+	app.Immich.TUSEndPoint = app.TUSEndPoint
 
 	err = app.Immich.PingServer()
 	if err != nil {
@@ -122,10 +163,21 @@ func (app *Application) Run() error {
 	if err != nil {
 		return err
 	}
+
+	cache, err := hashcache.Open(app.HashCacheFile)
+	if err != nil {
+		return err
+	}
+	defer cache.Close()
+
 	localAssets := []localAsset{}
+	var totalSize int64
 
 	for _, p := range app.Paths {
-		fsys := os.DirFS(p)
+		fsys, err := source.Open(p)
+		if err != nil {
+			return fmt.Errorf("can't open %s: %w", p, err)
+		}
 
 		depth := 0
 		err = fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
@@ -139,21 +191,44 @@ func (app *Application) Run() error {
 				depth++
 				return nil
 			}
+			if strings.HasSuffix(path, ".json") {
+				// Takeout sidecar or album metadata, not a media asset.
+				return nil
+			}
 			info, err := d.Info()
 			if err != nil {
-				app.Logger.Println(chalk.Red, "can't stat %s: %s", path, err)
+				app.ErrLogger.Println(chalk.Red, "can't stat", path, ":", err, chalk.ResetColor)
+				return nil
+			}
+			id, err := cache.Hash(filepath.Join(p, path), info.ModTime().Unix(), info.Size(), func() (io.ReadCloser, error) {
+				return fsys.Open(path)
+			})
+			if err != nil {
+				app.ErrLogger.Println(chalk.Red, "can't hash", path, ":", err, chalk.ResetColor)
 				return nil
 			}
-			id := stripSpaces.ReplaceAllString(filepath.Base(d.Name()+"-"+strconv.FormatInt(info.Size(), 10)), "")
 			if app.OnLineAssets.Includes(id) {
 				app.Logger.Println(chalk.Green, chalk.Dim, path, "is already uploaded", chalk.ResetColor)
 				return nil
 			}
-			localAssets = append(localAssets, localAsset{
-				Fsys: fsys.(fs.StatFS),
+			asset := localAsset{
+				Fsys: fsys,
 				Path: path,
 				ID:   id,
-			})
+				Size: info.Size(),
+			}
+			if md, ok := source.ReadTakeoutMetadata(fsys, path); ok {
+				asset.CreatedAt = md.CreatedAt
+				asset.ModifiedAt = md.ModifiedAt
+				asset.TakeoutAlbum = md.Album
+				if md.Latitude != 0 || md.Longitude != 0 {
+					asset.Latitude = md.Latitude
+					asset.Longitude = md.Longitude
+					asset.HasGeoData = true
+				}
+			}
+			localAssets = append(localAssets, asset)
+			totalSize += info.Size()
 			return nil
 		})
 		if err != nil {
@@ -167,6 +242,22 @@ func (app *Application) Run() error {
 	}
 
 	app.Logger.Println(chalk.Green, "Indexing complete, found", len(localAssets), "local assets to upload", chalk.ResetColor)
+	app.Progress.SetTotal(totalSize)
+	defer app.Progress.Finish()
+
+	needsAlbums := app.Album != ""
+	for _, a := range localAssets {
+		if a.TakeoutAlbum != "" {
+			needsAlbums = true
+			break
+		}
+	}
+	if needsAlbums {
+		app.albums, err = newAlbumDispatcher(ctx, app.Immich, app.Logger)
+		if err != nil {
+			return fmt.Errorf("can't prepare album assignment: %w", err)
+		}
+	}
 
 	if !app.Yes {
 		var s string
@@ -178,14 +269,17 @@ func (app *Application) Run() error {
 	}
 
 	app.Worker = NewWorker(int(app.Threads))
-	stop := app.Worker.Run()
+	stop := app.Worker.Run(ctx)
 	app.tooManyServerErrors = make(chan any)
 
 assetLoop:
 	for _, a := range localAssets {
 		select {
+		case <-ctx.Done():
+			app.Logger.Println(chalk.Yellow, "Interrupted, waiting for in-flight uploads to finish", chalk.ResetColor)
+			break assetLoop
 		case <-app.tooManyServerErrors:
-			app.Logger.Println(chalk.Red, "Too many server errors")
+			app.ErrLogger.Println(chalk.Red, "Too many server errors")
 			break assetLoop
 		default:
 			if app.OnLineAssets.Includes(a.ID) {
@@ -196,33 +290,52 @@ assetLoop:
 		}
 	}
 	stop()
+	if app.albums != nil {
+		app.albums.Flush()
+	}
 	return err
 }
 
 func (app *Application) Upload(a localAsset) {
-	app.Worker.Push(func() {
+	app.Worker.Push(func(ctx context.Context) {
 		if app.OnLineAssets.Includes(a.ID) {
 			app.Logger.Println(chalk.Yellow, filepath.Base(a.Path), "have been already uploaded", chalk.ResetColor)
 			return
 		}
 		app.OnLineAssets.Push(a.ID)
-		resp, err := app.Immich.AssetUpload(a.Fsys, a.Path)
+		resp, err := app.Immich.AssetUpload(ctx, a.Fsys, a.Path, func(r io.Reader) io.Reader {
+			return app.Progress.WrapReader(r)
+		}, immich.AssetMetadata{
+			CreatedAt:  a.CreatedAt,
+			ModifiedAt: a.ModifiedAt,
+			Latitude:   a.Latitude,
+			Longitude:  a.Longitude,
+			HasGeoData: a.HasGeoData,
+		})
 
 		if err != nil {
 			if errors.Is(err, immich.LocalFileError(nil)) || errors.Is(err, &immich.UnsupportedMedia{}) {
-				app.Logger.Println(chalk.Yellow, "Can't upload file:", a.Path, err, chalk.ResetColor)
+				app.ErrLogger.Println(chalk.Yellow, "Can't upload file:", a.Path, err, chalk.ResetColor)
 			} else if errors.Is(err, &immich.TooManyInternalError{}) {
 				close(app.tooManyServerErrors)
 			} else {
-				app.Logger.Println(chalk.Red, "Can't upload file:", a.Path)
-				app.Logger.Println(chalk.Red, err, chalk.ResetColor)
+				app.ErrLogger.Println(chalk.Red, "Can't upload file:", a.Path)
+				app.ErrLogger.Println(chalk.Red, err, chalk.ResetColor)
 			}
 			return
 		}
 
 		app.mediaCount.Add(1)
 		app.Logger.Println(chalk.Green, filepath.Base(a.Path), "uploaded.", app.mediaCount.Load(), chalk.ResetColor)
-		_ = resp
+		if app.albums != nil {
+			name := a.TakeoutAlbum
+			if name == "" {
+				name = albumNameFor(app.Album, a.Path)
+			}
+			if name != "" {
+				app.albums.Add(name, resp.ID)
+			}
+		}
 		if app.Delete {
 			// TODO
 		}
@@ -0,0 +1,40 @@
+package progress
+
+import (
+	"io"
+
+	"github.com/cheggaaa/pb/v3"
+)
+
+// PBReporter is the default Reporter, built on github.com/cheggaaa/pb/v3.
+// It renders a single bar tracking total bytes uploaded across every
+// queued asset, with ETA and throughput.
+type PBReporter struct {
+	bar *pb.ProgressBar
+}
+
+// NewPBReporter creates a PBReporter. Call SetTotal once the total upload
+// size is known to start the bar.
+func NewPBReporter() *PBReporter {
+	bar := pb.New64(0)
+	bar.Set(pb.Bytes, true)
+	bar.SetTemplateString(`{{counters . }} {{bar . }} {{percent . }} {{speed . }} {{rtime . "ETA %s"}}`)
+	return &PBReporter{bar: bar}
+}
+
+// SetTotal sets the total number of bytes to upload and starts the bar.
+func (r *PBReporter) SetTotal(bytes int64) {
+	r.bar.SetTotal(bytes)
+	r.bar.Start()
+}
+
+// WrapReader wraps rd so that each read advances the shared bar. There is
+// no per-file display: every asset's reads feed the same aggregate bar.
+func (r *PBReporter) WrapReader(rd io.Reader) io.Reader {
+	return r.bar.NewProxyReader(rd)
+}
+
+// Finish stops the bar, leaving the final state on screen.
+func (r *PBReporter) Finish() {
+	r.bar.Finish()
+}
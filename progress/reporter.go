@@ -0,0 +1,27 @@
+// Package progress reports upload progress to the user, independently of
+// how the bytes are actually transferred.
+package progress
+
+import "io"
+
+// Reporter tracks progress across all queued assets. It only reports the
+// aggregate: there's no per-file display, in-flight or otherwise.
+type Reporter interface {
+	// SetTotal sets the total number of bytes to be uploaded, known once
+	// the local walk has completed.
+	SetTotal(bytes int64)
+	// WrapReader wraps r so that reads from it advance the aggregate
+	// progress.
+	WrapReader(r io.Reader) io.Reader
+	// Finish marks the run as complete and flushes any remaining display.
+	Finish()
+}
+
+// Nop is a Reporter that does nothing, used for --no-progress and --silent.
+type Nop struct{}
+
+func (Nop) SetTotal(int64) {}
+
+func (Nop) WrapReader(r io.Reader) io.Reader { return r }
+
+func (Nop) Finish() {}
@@ -0,0 +1,255 @@
+package source
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"time"
+)
+
+func openZip(path string) (fs.FS, error) {
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, fmt.Errorf("can't open %s: %w", path, err)
+	}
+	return r, nil
+}
+
+// tgzEntry is what tarGzFS knows about one file in the archive: where its
+// data lives in the spool file, and its directory-entry metadata.
+type tgzEntry struct {
+	offset  int64
+	size    int64
+	modTime time.Time
+	isDir   bool
+}
+
+// tarGzFS is a streaming fs.FS over a .tar.gz archive. Rather than holding
+// every file's content in memory (which, for a real Google Photos Takeout
+// export, can be tens to hundreds of gigabytes), it spools the decompressed
+// tar data once to a temporary file and serves each entry's content as an
+// independent, offset-scoped view of that file.
+type tarGzFS struct {
+	spool    *os.File
+	entries  map[string]tgzEntry
+	children map[string][]string // directory path -> immediate child names
+}
+
+// openTarGz spools a .tar.gz archive to a temporary file and indexes its
+// entries, so the archive only needs to be decompressed once per run and
+// individual files can be read back without holding their content in
+// memory.
+func openTarGz(path string) (fs.FS, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("can't open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("can't open %s: %w", path, err)
+	}
+	defer gz.Close()
+
+	spool, err := os.CreateTemp("", "immich-go-targz-*")
+	if err != nil {
+		return nil, fmt.Errorf("can't create spool file for %s: %w", path, err)
+	}
+	// The spool file only needs to live as long as its file descriptor:
+	// unlinking it now means the OS reclaims the disk space as soon as the
+	// archive's fs.FS is no longer referenced, even if the process is
+	// killed before it gets a chance to close it.
+	if err = os.Remove(spool.Name()); err != nil {
+		spool.Close()
+		return nil, fmt.Errorf("can't unlink spool file for %s: %w", path, err)
+	}
+
+	tfs := &tarGzFS{
+		spool:    spool,
+		entries:  map[string]tgzEntry{".": {isDir: true}},
+		children: map[string][]string{},
+	}
+
+	var written int64
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			spool.Close()
+			return nil, fmt.Errorf("can't read %s: %w", path, err)
+		}
+		name := cleanTarName(hdr.Name)
+		if name == "" || name == "." {
+			continue
+		}
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			tfs.addDir(name, hdr.ModTime)
+		case tar.TypeReg:
+			tfs.addDir(cleanTarName(dirOf(name)), time.Time{})
+			n, err := io.Copy(spool, tr)
+			if err != nil {
+				spool.Close()
+				return nil, fmt.Errorf("can't read %s from %s: %w", hdr.Name, path, err)
+			}
+			tfs.entries[name] = tgzEntry{offset: written, size: n, modTime: hdr.ModTime}
+			tfs.addChild(name)
+			written += n
+		default:
+			// Symlinks, hard links, etc. aren't media files; skip them.
+		}
+	}
+	return tfs, nil
+}
+
+func cleanTarName(name string) string {
+	return path.Clean("/" + name)[1:]
+}
+
+func dirOf(name string) string {
+	d := path.Dir(name)
+	if d == "/" {
+		return "."
+	}
+	return d
+}
+
+// addDir records name and every one of its ancestors as a directory, so
+// ReadDir works even for directories the archive didn't list explicitly
+// (tar producers commonly only emit TypeDir headers for some directories,
+// or none at all).
+func (t *tarGzFS) addDir(name string, modTime time.Time) {
+	for name != "." && name != "" {
+		if e, ok := t.entries[name]; !ok {
+			t.entries[name] = tgzEntry{isDir: true, modTime: modTime}
+			t.addChild(name)
+		} else if !e.isDir {
+			break
+		}
+		name = dirOf(name)
+	}
+}
+
+func (t *tarGzFS) addChild(name string) {
+	dir := dirOf(name)
+	base := path.Base(name)
+	for _, c := range t.children[dir] {
+		if c == base {
+			return
+		}
+	}
+	t.children[dir] = append(t.children[dir], base)
+}
+
+func (t *tarGzFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	e, ok := t.entries[name]
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	if e.isDir {
+		return &tgzDirFile{name: name, fs: t}, nil
+	}
+	return &tgzFile{
+		name:   name,
+		entry:  e,
+		reader: io.NewSectionReader(t.spool, e.offset, e.size),
+	}, nil
+}
+
+func (t *tarGzFS) Stat(name string) (fs.FileInfo, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrInvalid}
+	}
+	e, ok := t.entries[name]
+	if !ok {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+	}
+	return tgzFileInfo{name: path.Base(name), entry: e}, nil
+}
+
+func (t *tarGzFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+	}
+	e, ok := t.entries[name]
+	if !ok || !e.isDir {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+	names := t.children[name]
+	list := make([]fs.DirEntry, 0, len(names))
+	for _, n := range names {
+		child := path.Join(name, n)
+		list = append(list, tgzDirEntry{info: tgzFileInfo{name: n, entry: t.entries[child]}})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name() < list[j].Name() })
+	return list, nil
+}
+
+// tgzFileInfo implements fs.FileInfo for one entry of a tarGzFS.
+type tgzFileInfo struct {
+	name  string
+	entry tgzEntry
+}
+
+func (i tgzFileInfo) Name() string { return i.name }
+func (i tgzFileInfo) Size() int64  { return i.entry.size }
+func (i tgzFileInfo) Mode() fs.FileMode {
+	if i.entry.isDir {
+		return fs.ModeDir | 0o755
+	}
+	return 0o644
+}
+func (i tgzFileInfo) ModTime() time.Time { return i.entry.modTime }
+func (i tgzFileInfo) IsDir() bool        { return i.entry.isDir }
+func (i tgzFileInfo) Sys() any           { return nil }
+
+// tgzDirEntry implements fs.DirEntry for one entry of a tarGzFS.
+type tgzDirEntry struct {
+	info tgzFileInfo
+}
+
+func (e tgzDirEntry) Name() string               { return e.info.name }
+func (e tgzDirEntry) IsDir() bool                { return e.info.IsDir() }
+func (e tgzDirEntry) Type() fs.FileMode          { return e.info.Mode().Type() }
+func (e tgzDirEntry) Info() (fs.FileInfo, error) { return e.info, nil }
+
+// tgzFile is a read-only view of one regular file's data in the spool.
+type tgzFile struct {
+	name   string
+	entry  tgzEntry
+	reader *io.SectionReader
+}
+
+func (f *tgzFile) Stat() (fs.FileInfo, error) {
+	return tgzFileInfo{name: path.Base(f.name), entry: f.entry}, nil
+}
+func (f *tgzFile) Read(p []byte) (int, error) { return f.reader.Read(p) }
+func (f *tgzFile) Close() error               { return nil }
+
+// tgzDirFile is a read-only handle on a directory, sufficient for fs.FS
+// consumers that Open a directory rather than using ReadDir directly.
+type tgzDirFile struct {
+	name string
+	fs   *tarGzFS
+}
+
+func (f *tgzDirFile) Stat() (fs.FileInfo, error) {
+	return tgzFileInfo{name: path.Base(f.name), entry: f.fs.entries[f.name]}, nil
+}
+func (f *tgzDirFile) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: f.name, Err: fs.ErrInvalid}
+}
+func (f *tgzDirFile) Close() error { return nil }
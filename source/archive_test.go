@@ -0,0 +1,187 @@
+package source
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/fs"
+	"os"
+	"sort"
+	"testing"
+	"time"
+)
+
+// buildTarGz writes a tar.gz to disk containing the given files. Parent
+// directories are never given their own tar header, matching the common
+// case of tar producers (including Google Takeout) that only emit headers
+// for regular files.
+func buildTarGz(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	f, err := os.CreateTemp(t.TempDir(), "archive-*.tar.gz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		content := files[name]
+		hdr := &tar.Header{
+			Name:    name,
+			Mode:    0o644,
+			Size:    int64(len(content)),
+			ModTime: time.Unix(1700000000, 0),
+		}
+		if err = tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err = tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err = gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return f.Name()
+}
+
+func TestTarGzFSReadsFileContent(t *testing.T) {
+	path := buildTarGz(t, map[string]string{
+		"album/photo.jpg": "jpeg bytes",
+	})
+	fsys, err := openTarGz(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := fs.ReadFile(fsys, "album/photo.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "jpeg bytes" {
+		t.Errorf("content = %q, want %q", b, "jpeg bytes")
+	}
+}
+
+func TestTarGzFSStatImplicitParentDirs(t *testing.T) {
+	path := buildTarGz(t, map[string]string{
+		"a/b/photo.jpg": "data",
+	})
+	fsys, err := openTarGz(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, dir := range []string{"a", "a/b"} {
+		info, err := fs.Stat(fsys, dir)
+		if err != nil {
+			t.Fatalf("Stat(%q): %v", dir, err)
+		}
+		if !info.IsDir() {
+			t.Errorf("Stat(%q).IsDir() = false, want true", dir)
+		}
+	}
+}
+
+func TestTarGzFSReadDirImplicitParentDirs(t *testing.T) {
+	path := buildTarGz(t, map[string]string{
+		"a/b/one.jpg": "1",
+		"a/b/two.jpg": "2",
+		"a/other.jpg": "3",
+	})
+	fsys, err := openTarGz(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := fs.ReadDir(fsys, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	sort.Strings(names)
+	want := []string{"b", "other.jpg"}
+	if len(names) != len(want) || names[0] != want[0] || names[1] != want[1] {
+		t.Errorf("ReadDir(\"a\") names = %v, want %v", names, want)
+	}
+
+	entries, err = fs.ReadDir(fsys, "a/b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	names = nil
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	sort.Strings(names)
+	want = []string{"one.jpg", "two.jpg"}
+	if len(names) != len(want) || names[0] != want[0] || names[1] != want[1] {
+		t.Errorf("ReadDir(\"a/b\") names = %v, want %v", names, want)
+	}
+}
+
+func TestTarGzFSOpenMissingFile(t *testing.T) {
+	path := buildTarGz(t, map[string]string{"a.jpg": "1"})
+	fsys, err := openTarGz(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = fsys.Open("missing.jpg")
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if !bytes.Contains([]byte(err.Error()), []byte("missing.jpg")) {
+		t.Errorf("Open(missing) error = %v, want it to mention the path", err)
+	}
+	if pe, ok := err.(*fs.PathError); !ok || pe.Err != fs.ErrNotExist {
+		t.Errorf("Open(missing) error = %v (%T), want *fs.PathError wrapping fs.ErrNotExist", err, err)
+	}
+}
+
+func TestTarGzFSMultipleFilesIndependentReaders(t *testing.T) {
+	path := buildTarGz(t, map[string]string{
+		"one.jpg": "first",
+		"two.jpg": "second",
+	})
+	fsys, err := openTarGz(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f1, err := fsys.Open("one.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f2, err := fsys.Open("two.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b2, err := io.ReadAll(f2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b1, err := io.ReadAll(f1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b1) != "first" || string(b2) != "second" {
+		t.Errorf("got b1=%q b2=%q, want b1=\"first\" b2=\"second\"", b1, b2)
+	}
+}
@@ -0,0 +1,47 @@
+// Package source turns a path given on the command line into an fs.FS,
+// whether that path is a plain directory, an archive, or a Google Photos
+// Takeout export, so the rest of immich-go can walk it uniformly.
+package source
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+)
+
+// Opener returns an fs.FS view of the source located at path.
+type Opener func(path string) (fs.FS, error)
+
+// openers maps a recognized file extension (lower-case, including the
+// leading dot) to the Opener used to read it.
+var openers = map[string]Opener{
+	".zip":    openZip,
+	".tar.gz": openTarGz,
+	".tgz":    openTarGz,
+}
+
+// Register adds or overrides the Opener used for paths ending in ext (for
+// example ".zip"). It lets callers plug in additional archive formats.
+func Register(ext string, open Opener) {
+	openers[strings.ToLower(ext)] = open
+}
+
+// Open returns an fs.FS for path: a plain directory is served as-is, a
+// recognized archive is opened through its registered Opener.
+func Open(path string) (fs.FS, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return os.DirFS(path), nil
+	}
+	lower := strings.ToLower(path)
+	for ext, open := range openers {
+		if strings.HasSuffix(lower, ext) {
+			return open(path)
+		}
+	}
+	return nil, fmt.Errorf("don't know how to open %s as a source", path)
+}
@@ -0,0 +1,64 @@
+package source
+
+import (
+	"encoding/json"
+	"io/fs"
+	"path"
+	"strconv"
+	"time"
+)
+
+// Metadata is what immich-go can recover about a media file from a Google
+// Photos Takeout export: the sidecar JSON next to the file itself, plus
+// the metadata.json of the album folder it lives in.
+type Metadata struct {
+	CreatedAt  time.Time
+	ModifiedAt time.Time
+	Latitude   float64
+	Longitude  float64
+	Album      string
+}
+
+type takeoutPhotoMeta struct {
+	PhotoTakenTime struct {
+		Timestamp string `json:"timestamp"`
+	} `json:"photoTakenTime"`
+	GeoData struct {
+		Latitude  float64 `json:"latitude"`
+		Longitude float64 `json:"longitude"`
+	} `json:"geoData"`
+}
+
+type takeoutAlbumMeta struct {
+	Title string `json:"title"`
+}
+
+// ReadTakeoutMetadata looks for mediaPath's Takeout JSON sidecar
+// ("name.jpg.json") and for its containing folder's album metadata.json.
+// ok is false when neither is found, which is the common case for sources
+// that aren't a Takeout export.
+func ReadTakeoutMetadata(fsys fs.FS, mediaPath string) (md Metadata, ok bool) {
+	if b, err := fs.ReadFile(fsys, mediaPath+".json"); err == nil {
+		var pm takeoutPhotoMeta
+		if json.Unmarshal(b, &pm) == nil {
+			ok = true
+			if ts, err := strconv.ParseInt(pm.PhotoTakenTime.Timestamp, 10, 64); err == nil {
+				md.CreatedAt = time.Unix(ts, 0)
+				md.ModifiedAt = md.CreatedAt
+			}
+			md.Latitude = pm.GeoData.Latitude
+			md.Longitude = pm.GeoData.Longitude
+		}
+	}
+
+	albumMetaPath := path.Join(path.Dir(mediaPath), "metadata.json")
+	if b, err := fs.ReadFile(fsys, albumMetaPath); err == nil {
+		var am takeoutAlbumMeta
+		if json.Unmarshal(b, &am) == nil && am.Title != "" {
+			ok = true
+			md.Album = am.Title
+		}
+	}
+
+	return md, ok
+}
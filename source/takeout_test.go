@@ -0,0 +1,66 @@
+package source
+
+import (
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestReadTakeoutMetadataSidecarAndAlbum(t *testing.T) {
+	fsys := fstest.MapFS{
+		"Takeout/album/photo.jpg": &fstest.MapFile{Data: []byte("jpeg bytes")},
+		"Takeout/album/photo.jpg.json": &fstest.MapFile{Data: []byte(`{
+			"photoTakenTime": {"timestamp": "1700000000"},
+			"geoData": {"latitude": 48.85, "longitude": 2.35}
+		}`)},
+		"Takeout/album/metadata.json": &fstest.MapFile{Data: []byte(`{"title": "Summer Trip"}`)},
+	}
+
+	md, ok := ReadTakeoutMetadata(fsys, "Takeout/album/photo.jpg")
+	if !ok {
+		t.Fatal("ok = false, want true")
+	}
+	wantTime := time.Unix(1700000000, 0)
+	if !md.CreatedAt.Equal(wantTime) {
+		t.Errorf("CreatedAt = %v, want %v", md.CreatedAt, wantTime)
+	}
+	if !md.ModifiedAt.Equal(wantTime) {
+		t.Errorf("ModifiedAt = %v, want %v", md.ModifiedAt, wantTime)
+	}
+	if md.Latitude != 48.85 || md.Longitude != 2.35 {
+		t.Errorf("Latitude/Longitude = %v/%v, want 48.85/2.35", md.Latitude, md.Longitude)
+	}
+	if md.Album != "Summer Trip" {
+		t.Errorf("Album = %q, want %q", md.Album, "Summer Trip")
+	}
+}
+
+func TestReadTakeoutMetadataNoSidecar(t *testing.T) {
+	fsys := fstest.MapFS{
+		"photo.jpg": &fstest.MapFile{Data: []byte("jpeg bytes")},
+	}
+	md, ok := ReadTakeoutMetadata(fsys, "photo.jpg")
+	if ok {
+		t.Errorf("ok = true, want false when neither sidecar nor album metadata exist")
+	}
+	if !md.CreatedAt.IsZero() {
+		t.Errorf("CreatedAt = %v, want zero value", md.CreatedAt)
+	}
+}
+
+func TestReadTakeoutMetadataAlbumOnly(t *testing.T) {
+	fsys := fstest.MapFS{
+		"album/photo.jpg":     &fstest.MapFile{Data: []byte("jpeg bytes")},
+		"album/metadata.json": &fstest.MapFile{Data: []byte(`{"title": "Winter"}`)},
+	}
+	md, ok := ReadTakeoutMetadata(fsys, "album/photo.jpg")
+	if !ok {
+		t.Fatal("ok = false, want true")
+	}
+	if md.Album != "Winter" {
+		t.Errorf("Album = %q, want %q", md.Album, "Winter")
+	}
+	if !md.CreatedAt.IsZero() {
+		t.Errorf("CreatedAt = %v, want zero value (no sidecar present)", md.CreatedAt)
+	}
+}
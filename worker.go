@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"sync"
+)
+
+// Worker runs pushed jobs on a fixed-size pool of goroutines.
+type Worker struct {
+	jobs chan func(context.Context)
+	wg   sync.WaitGroup
+	n    int
+	ctx  context.Context
+}
+
+// NewWorker creates a Worker with n concurrent goroutines.
+func NewWorker(n int) *Worker {
+	if n < 1 {
+		n = 1
+	}
+	return &Worker{
+		jobs: make(chan func(context.Context)),
+		n:    n,
+	}
+}
+
+// Run starts the worker goroutines bound to ctx and returns a stop function
+// that waits for in-flight jobs to finish. Cancelling ctx stops goroutines
+// from picking up any more queued jobs.
+func (w *Worker) Run(ctx context.Context) (stop func()) {
+	w.ctx = ctx
+	w.wg.Add(w.n)
+	for i := 0; i < w.n; i++ {
+		go func() {
+			defer w.wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case job, ok := <-w.jobs:
+					if !ok {
+						return
+					}
+					job(ctx)
+				}
+			}
+		}()
+	}
+	return func() {
+		close(w.jobs)
+		w.wg.Wait()
+	}
+}
+
+// Push queues fn to run on the next available worker goroutine. It gives up
+// and drops fn if ctx is cancelled before a worker picks it up, so callers
+// can't block forever on a worker pool whose goroutines have all already
+// exited because of cancellation.
+func (w *Worker) Push(fn func(context.Context)) {
+	select {
+	case w.jobs <- fn:
+	case <-w.ctx.Done():
+	}
+}
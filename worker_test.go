@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWorkerRunsPushedJobs(t *testing.T) {
+	w := NewWorker(2)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stop := w.Run(ctx)
+
+	var ran atomic.Int64
+	for i := 0; i < 5; i++ {
+		w.Push(func(context.Context) { ran.Add(1) })
+	}
+	stop()
+
+	if got := ran.Load(); got != 5 {
+		t.Fatalf("ran = %d, want 5", got)
+	}
+}
+
+func TestWorkerPushReturnsAfterCancel(t *testing.T) {
+	w := NewWorker(1)
+	ctx, cancel := context.WithCancel(context.Background())
+	stop := w.Run(ctx)
+
+	// Occupy the only worker so further pushes must queue.
+	block := make(chan struct{})
+	w.Push(func(context.Context) { <-block })
+
+	cancel()
+	done := make(chan struct{})
+	go func() {
+		w.Push(func(context.Context) {})
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Push did not return after ctx was cancelled")
+	}
+
+	close(block)
+	stop()
+}